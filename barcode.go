@@ -0,0 +1,261 @@
+package msd347
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/codabar"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/code39"
+	"github.com/boombuler/barcode/code93"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"github.com/boombuler/barcode/twooffive"
+)
+
+// BarcodeSymbology selects the symbology passed to GS k. Values match
+// the "new" GS k m parameter range (m >= 65), which takes an explicit
+// length byte instead of a NUL terminator.
+type BarcodeSymbology byte
+
+const (
+	BarcodeUPCA    BarcodeSymbology = 65
+	BarcodeUPCE    BarcodeSymbology = 66
+	BarcodeEAN13   BarcodeSymbology = 67
+	BarcodeEAN8    BarcodeSymbology = 68
+	BarcodeCode39  BarcodeSymbology = 69
+	BarcodeITF     BarcodeSymbology = 70
+	BarcodeCodabar BarcodeSymbology = 71
+	BarcodeCode93  BarcodeSymbology = 72
+	BarcodeCode128 BarcodeSymbology = 73
+)
+
+// HRIPosition selects where the human-readable interpretation line is
+// printed relative to the bars (GS H).
+type HRIPosition byte
+
+const (
+	HRINone  HRIPosition = 0
+	HRIAbove HRIPosition = 1
+	HRIBelow HRIPosition = 2
+	HRIBoth  HRIPosition = 3
+)
+
+// HRIFont selects the font used for the HRI line (GS f).
+type HRIFont byte
+
+const (
+	HRIFontA HRIFont = 0
+	HRIFontB HRIFont = 1
+)
+
+// BarcodeOptions configures how PrintBarcode renders a symbology,
+// whether natively or via the raster fallback.
+type BarcodeOptions struct {
+	// Height is the bar height in dots (GS h). Zero uses the printer's
+	// existing setting.
+	Height int
+	// Width is the narrow-bar module width, 2-6 (GS w). Zero uses the
+	// printer's existing setting.
+	Width int
+
+	HRIPosition HRIPosition
+	HRIFont     HRIFont
+}
+
+// PrintBarcode prints data in the given symbology, either with the
+// printer's native GS k command or, if PrinterCaps says the firmware
+// doesn't support it, by rendering it to an image and routing it
+// through PrintImage.
+func (p *Printer) PrintBarcode(sym BarcodeSymbology, data string, opts BarcodeOptions) error {
+	if !p.caps.supportsNativeBarcode(sym) {
+		return p.printBarcodeRaster(sym, data, opts)
+	}
+
+	if len(data) > 255 {
+		return fmt.Errorf("barcode data too long: %d bytes > max 255", len(data))
+	}
+
+	if opts.Height > 0 {
+		if _, err := p.conn.Write([]byte{GS, 'h', byte(opts.Height)}); err != nil {
+			return err
+		}
+	}
+	if opts.Width > 0 {
+		if _, err := p.conn.Write([]byte{GS, 'w', byte(opts.Width)}); err != nil {
+			return err
+		}
+	}
+	if _, err := p.conn.Write([]byte{GS, 'H', byte(opts.HRIPosition)}); err != nil {
+		return err
+	}
+	if _, err := p.conn.Write([]byte{GS, 'f', byte(opts.HRIFont)}); err != nil {
+		return err
+	}
+
+	cmd := []byte{GS, 'k', byte(sym), byte(len(data))}
+	cmd = append(cmd, []byte(data)...)
+
+	_, err := p.conn.Write(cmd)
+	return err
+}
+
+func (p *Printer) printBarcodeRaster(sym BarcodeSymbology, data string, opts BarcodeOptions) error {
+	bc, err := encodeBarcode(sym, data)
+	if err != nil {
+		return err
+	}
+
+	width := bc.Bounds().Dx()
+	if opts.Width > 1 {
+		width *= opts.Width
+	}
+	height := opts.Height
+	if height <= 0 {
+		height = 80
+	}
+
+	scaled, err := barcode.Scale(bc, width, height)
+	if err != nil {
+		return err
+	}
+
+	return p.PrintImage(context.Background(), scaled, PrintNormal)
+}
+
+func encodeBarcode(sym BarcodeSymbology, data string) (barcode.Barcode, error) {
+	switch sym {
+	case BarcodeUPCA, BarcodeEAN13, BarcodeEAN8:
+		return ean.Encode(data)
+	case BarcodeUPCE:
+		// github.com/boombuler/barcode has no UPC-E encoder (its ean
+		// package only produces EAN-8/EAN-13/UPC-A symbols), so there's
+		// no raster fallback to offer here. Printers lacking native GS k
+		// support for UPC-E can't print it through this path.
+		return nil, fmt.Errorf("raster fallback for barcode symbology UPC-E is not supported")
+	case BarcodeCode39:
+		return code39.Encode(data, false, true)
+	case BarcodeITF:
+		return twooffive.Encode(data, true)
+	case BarcodeCodabar:
+		return codabar.Encode(data)
+	case BarcodeCode93:
+		return code93.Encode(data, true, true)
+	case BarcodeCode128:
+		return code128.Encode(data)
+	default:
+		return nil, fmt.Errorf("unsupported barcode symbology %d", sym)
+	}
+}
+
+// QRErrorCorrection selects the QR error-correction level (GS ( k
+// function 169). Values match the symbol's usual L/M/Q/H ordering.
+type QRErrorCorrection byte
+
+const (
+	QRErrorCorrectionL QRErrorCorrection = iota
+	QRErrorCorrectionM
+	QRErrorCorrectionQ
+	QRErrorCorrectionH
+)
+
+// QROptions configures PrintQR.
+type QROptions struct {
+	// Model is the QR model select parameter (GS ( k function 165),
+	// 1 or 2. Zero defaults to model 2.
+	Model byte
+	// ModuleSize is dots per module (GS ( k function 167), 1-16. Zero
+	// defaults to 4.
+	ModuleSize int
+
+	ErrorCorrection QRErrorCorrection
+}
+
+const maxQRData = 7089
+
+// PrintQR prints data as a QR code using the GS ( k command family, or
+// falls back to rasterizing it through PrintImage if PrinterCaps says
+// the firmware doesn't support QR natively.
+func (p *Printer) PrintQR(data string, opts QROptions) error {
+	if !p.caps.supportsNativeQR() {
+		return p.printQRRaster(data, opts)
+	}
+
+	if len(data) > maxQRData {
+		return fmt.Errorf("qr data too long: %d bytes > max %d", len(data), maxQRData)
+	}
+
+	model := opts.Model
+	if model == 0 {
+		model = 2
+	}
+	if model != 1 && model != 2 {
+		return fmt.Errorf("qr model %d out of range: must be 1 or 2", model)
+	}
+	if err := p.gsLeftParenK(49, 65, '0'+model, 0); err != nil {
+		return err
+	}
+
+	moduleSize := byte(opts.ModuleSize)
+	if moduleSize == 0 {
+		moduleSize = 4
+	}
+	if err := p.gsLeftParenK(49, 67, moduleSize); err != nil {
+		return err
+	}
+
+	if err := p.gsLeftParenK(49, 69, '0'+byte(opts.ErrorCorrection)); err != nil {
+		return err
+	}
+
+	store := append([]byte{'0'}, []byte(data)...)
+	if err := p.gsLeftParenK(49, 80, store...); err != nil {
+		return err
+	}
+
+	return p.gsLeftParenK(49, 81, '0')
+}
+
+func (p *Printer) printQRRaster(data string, opts QROptions) error {
+	ec := qr.L
+	switch opts.ErrorCorrection {
+	case QRErrorCorrectionM:
+		ec = qr.M
+	case QRErrorCorrectionQ:
+		ec = qr.Q
+	case QRErrorCorrectionH:
+		ec = qr.H
+	}
+
+	bc, err := qr.Encode(data, ec, qr.Auto)
+	if err != nil {
+		return err
+	}
+
+	size := bc.Bounds().Dx()
+	if opts.ModuleSize > 1 {
+		size *= opts.ModuleSize
+	}
+
+	scaled, err := barcode.Scale(bc, size, size)
+	if err != nil {
+		return err
+	}
+
+	return p.PrintImage(context.Background(), scaled, PrintNormal)
+}
+
+// gsLeftParenK sends one GS ( k function-code command: the parameter
+// length pL/pH encodes cn, fn, and params together.
+func (p *Printer) gsLeftParenK(cn, fn byte, params ...byte) error {
+	n := len(params) + 2
+	pL := byte(n & 0xFF)
+	pH := byte((n >> 8) & 0xFF)
+
+	cmd := []byte{GS, '(', 'k', pL, pH, cn, fn}
+	cmd = append(cmd, params...)
+
+	_, err := p.conn.Write(cmd)
+	return err
+}
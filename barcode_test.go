@@ -0,0 +1,81 @@
+package msd347
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGsLeftParenK(t *testing.T) {
+	ft := &fakeTransport{}
+	p := &Printer{conn: ft}
+
+	if err := p.gsLeftParenK(49, 67, 4); err != nil {
+		t.Fatalf("gsLeftParenK: %v", err)
+	}
+
+	// n = len(params) + 2 = 1 + 2 = 3.
+	want := []byte{GS, '(', 'k', 3, 0, 49, 67, 4}
+	if !bytes.Equal(ft.Written.Bytes(), want) {
+		t.Errorf("gsLeftParenK wrote %v, want %v", ft.Written.Bytes(), want)
+	}
+}
+
+func TestGsLeftParenKLongParams(t *testing.T) {
+	ft := &fakeTransport{}
+	p := &Printer{conn: ft}
+
+	params := make([]byte, 300)
+	if err := p.gsLeftParenK(49, 80, params...); err != nil {
+		t.Fatalf("gsLeftParenK: %v", err)
+	}
+
+	// n = len(params) + 2 = 302 = 0x12E, so pL = 0x2E, pH = 0x01.
+	got := ft.Written.Bytes()
+	if len(got) != 7+300 {
+		t.Fatalf("gsLeftParenK wrote %d bytes, want %d", len(got), 7+300)
+	}
+	if got[3] != 0x2E || got[4] != 0x01 {
+		t.Errorf("gsLeftParenK pL,pH = %d,%d, want 0x2E,0x01", got[3], got[4])
+	}
+}
+
+func TestEncodeBarcodeDispatch(t *testing.T) {
+	cases := []struct {
+		name string
+		sym  BarcodeSymbology
+		data string
+	}{
+		{"UPC-A", BarcodeUPCA, "012345678905"},
+		{"EAN-13", BarcodeEAN13, "4006381333931"},
+		{"EAN-8", BarcodeEAN8, "96385074"},
+		{"Code39", BarcodeCode39, "CODE39"},
+		{"ITF", BarcodeITF, "12345678"},
+		{"Codabar", BarcodeCodabar, "A12345A"},
+		{"Code93", BarcodeCode93, "CODE93"},
+		{"Code128", BarcodeCode128, "Code128"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bc, err := encodeBarcode(c.sym, c.data)
+			if err != nil {
+				t.Fatalf("encodeBarcode: %v", err)
+			}
+			if bc == nil {
+				t.Fatal("encodeBarcode returned a nil Barcode with no error")
+			}
+		})
+	}
+}
+
+func TestEncodeBarcodeUPCEUnsupported(t *testing.T) {
+	if _, err := encodeBarcode(BarcodeUPCE, "01234565"); err == nil {
+		t.Fatal("expected an error: the raster fallback has no UPC-E encoder")
+	}
+}
+
+func TestEncodeBarcodeUnknownSymbology(t *testing.T) {
+	if _, err := encodeBarcode(BarcodeSymbology(0), "data"); err == nil {
+		t.Fatal("expected an error for an unsupported symbology")
+	}
+}
@@ -0,0 +1,94 @@
+package msd347
+
+import "fmt"
+
+// PrinterCaps records which barcode/QR features a connected printer
+// supports natively, so PrintBarcode and PrintQR know when to fall
+// back to rasterizing through PrintImage instead.
+type PrinterCaps struct {
+	nativeBarcodes map[BarcodeSymbology]bool
+	nativeQR       bool
+}
+
+func (c *PrinterCaps) supportsNativeBarcode(sym BarcodeSymbology) bool {
+	if c == nil {
+		return true
+	}
+
+	native, known := c.nativeBarcodes[sym]
+	if !known {
+		return true
+	}
+
+	return native
+}
+
+func (c *PrinterCaps) supportsNativeQR() bool {
+	if c == nil {
+		return true
+	}
+
+	return c.nativeQR
+}
+
+// defaultPrinterCaps covers the subset every MSD347 clone seen in the
+// wild supports natively. CODE93 is the one symbology that's
+// inconsistently implemented, so it's rasterized unless a probe or
+// WithPrinterCaps says otherwise. UPC-E has no raster fallback at all
+// (see encodeBarcode), so a variant that probes false for it natively
+// will fail outright rather than silently rasterize it wrong.
+func defaultPrinterCaps() *PrinterCaps {
+	return &PrinterCaps{
+		nativeBarcodes: map[BarcodeSymbology]bool{
+			BarcodeUPCA:    true,
+			BarcodeUPCE:    true,
+			BarcodeEAN13:   true,
+			BarcodeEAN8:    true,
+			BarcodeCode39:  true,
+			BarcodeITF:     true,
+			BarcodeCodabar: true,
+			BarcodeCode93:  false,
+			BarcodeCode128: true,
+		},
+		nativeQR: true,
+	}
+}
+
+// knownPrinterCaps maps a GS I n=2 type ID to a PrinterCaps for firmware
+// variants known to deviate from defaultPrinterCaps. Empty until a
+// variant with different support is identified in the field.
+var knownPrinterCaps = map[byte]*PrinterCaps{}
+
+// probePrinterCaps asks the printer for its type ID (GS I) and looks it
+// up in knownPrinterCaps, falling back to defaultPrinterCaps for
+// unrecognized or unreadable IDs.
+func probePrinterCaps(t Transport) *PrinterCaps {
+	id, err := queryPrinterTypeID(t)
+	if err != nil {
+		return defaultPrinterCaps()
+	}
+
+	if caps, ok := knownPrinterCaps[id]; ok {
+		return caps
+	}
+
+	return defaultPrinterCaps()
+}
+
+func queryPrinterTypeID(t Transport) (byte, error) {
+	cmd := []byte{GS, 'I', 2}
+	if _, err := t.Write(cmd); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1)
+	n, err := t.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("printer type id: expected to read byte")
+	}
+
+	return buf[0], nil
+}
@@ -0,0 +1,103 @@
+package msd347
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSupportsNativeBarcodeNilReceiver(t *testing.T) {
+	var c *PrinterCaps
+	if !c.supportsNativeBarcode(BarcodeCode93) {
+		t.Error("nil *PrinterCaps should report every symbology native")
+	}
+}
+
+func TestSupportsNativeBarcodeUnknownSymbology(t *testing.T) {
+	c := &PrinterCaps{nativeBarcodes: map[BarcodeSymbology]bool{BarcodeCode93: false}}
+	if !c.supportsNativeBarcode(BarcodeCode128) {
+		t.Error("a symbology missing from nativeBarcodes should report native")
+	}
+}
+
+func TestSupportsNativeBarcodeKnownFalse(t *testing.T) {
+	c := &PrinterCaps{nativeBarcodes: map[BarcodeSymbology]bool{BarcodeCode93: false}}
+	if c.supportsNativeBarcode(BarcodeCode93) {
+		t.Error("a symbology explicitly marked false should report non-native")
+	}
+}
+
+func TestDefaultPrinterCaps(t *testing.T) {
+	c := defaultPrinterCaps()
+
+	for sym, want := range map[BarcodeSymbology]bool{
+		BarcodeUPCA:    true,
+		BarcodeUPCE:    true,
+		BarcodeEAN13:   true,
+		BarcodeEAN8:    true,
+		BarcodeCode39:  true,
+		BarcodeITF:     true,
+		BarcodeCodabar: true,
+		BarcodeCode93:  false,
+		BarcodeCode128: true,
+	} {
+		if got := c.supportsNativeBarcode(sym); got != want {
+			t.Errorf("supportsNativeBarcode(%d) = %v, want %v", sym, got, want)
+		}
+	}
+
+	if !c.supportsNativeQR() {
+		t.Error("defaultPrinterCaps should support native QR")
+	}
+}
+
+func TestSupportsNativeQRNilReceiver(t *testing.T) {
+	var c *PrinterCaps
+	if !c.supportsNativeQR() {
+		t.Error("nil *PrinterCaps should report QR native")
+	}
+}
+
+func TestQueryPrinterTypeID(t *testing.T) {
+	ft := &fakeTransport{}
+	ft.To.WriteByte(42)
+
+	id, err := queryPrinterTypeID(ft)
+	if err != nil {
+		t.Fatalf("queryPrinterTypeID: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("queryPrinterTypeID = %d, want 42", id)
+	}
+
+	want := []byte{GS, 'I', 2}
+	if !bytes.Equal(ft.Written.Bytes(), want) {
+		t.Errorf("queryPrinterTypeID wrote %v, want %v", ft.Written.Bytes(), want)
+	}
+}
+
+func TestQueryPrinterTypeIDShortRead(t *testing.T) {
+	ft := &fakeTransport{}
+
+	if _, err := queryPrinterTypeID(ft); err == nil {
+		t.Error("expected an error when the transport has no reply queued")
+	}
+}
+
+func TestProbePrinterCapsFallsBackOnReadError(t *testing.T) {
+	ft := &fakeTransport{}
+
+	caps := probePrinterCaps(ft)
+	if caps.supportsNativeBarcode(BarcodeCode93) {
+		t.Error("probePrinterCaps should fall back to defaultPrinterCaps (CODE93 non-native) on a read error")
+	}
+}
+
+func TestProbePrinterCapsUnrecognizedID(t *testing.T) {
+	ft := &fakeTransport{}
+	ft.To.WriteByte(0xFF)
+
+	caps := probePrinterCaps(ft)
+	if caps.supportsNativeBarcode(BarcodeCode93) {
+		t.Error("probePrinterCaps should fall back to defaultPrinterCaps for an unrecognized type ID")
+	}
+}
@@ -0,0 +1,102 @@
+//go:build !linux
+
+package msd347
+
+import (
+	"github.com/kylelemons/gousb/usb"
+)
+
+// libusbConn talks to the MSD347 over libusb. It's the only backend
+// available on non-Linux platforms; Linux builds use conn_usblp_linux.go
+// instead so they don't need libusb installed.
+type libusbConn struct {
+	ctx    *usb.Context
+	device *usb.Device
+
+	input  usb.Endpoint
+	output usb.Endpoint
+
+	maxPacketSize int
+}
+
+func (p *libusbConn) Close() error {
+	if err := p.device.Close(); err != nil {
+		return err
+	}
+	if err := p.ctx.Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *libusbConn) Read(buf []byte) (int, error) {
+	return p.output.Read(buf)
+}
+
+func (p *libusbConn) Write(buf []byte) (int, error) {
+	return p.input.Write(buf)
+}
+
+// MaxPacketSize implements PacketSizer using the OUT endpoint's
+// wMaxPacketSize, so PrintImage can chunk raster writes to match what
+// the device actually negotiated instead of a hardcoded guess.
+func (p *libusbConn) MaxPacketSize() int {
+	return p.maxPacketSize
+}
+
+func connectLibusb() (*libusbConn, error) {
+	ctx := usb.NewContext()
+
+	dev, err := ctx.OpenDeviceWithVidPid(0x0519, 0x2013)
+	if err != nil {
+		return nil, err
+	}
+
+	const inputEndpointAddr = 3
+
+	in, err := dev.OpenEndpoint(1, 0, 0, inputEndpointAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := dev.OpenEndpoint(1, 0, 0, 129)
+	if err != nil {
+		return nil, err
+	}
+
+	return &libusbConn{
+		device:        dev,
+		ctx:           ctx,
+		input:         in,
+		output:        out,
+		maxPacketSize: endpointMaxPacketSize(dev, inputEndpointAddr),
+	}, nil
+}
+
+// endpointMaxPacketSize looks up wMaxPacketSize for the given endpoint
+// address from the device's descriptor, falling back to 64 (the
+// standard full-speed bulk endpoint size) if it can't be found.
+func endpointMaxPacketSize(dev *usb.Device, addr uint8) int {
+	if dev.Descriptor == nil {
+		return 64
+	}
+
+	for _, cfg := range dev.Descriptor.Configs {
+		for _, iface := range cfg.Interfaces {
+			for _, setup := range iface.Setups {
+				for _, ep := range setup.Endpoints {
+					if ep.Address == addr {
+						return int(ep.MaxPacketSize)
+					}
+				}
+			}
+		}
+	}
+
+	return 64
+}
+
+func defaultTransport() (Transport, error) {
+	return connectLibusb()
+}
@@ -0,0 +1,161 @@
+//go:build linux
+
+package msd347
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// usblpDeviceIDSubstr is matched against the IEEE-1284 device ID string
+// reported by the kernel's usblp driver to pick an MSD347 out of
+// whatever else is bound to /dev/usblp*.
+const usblpDeviceIDSubstr = "MDL:MSD347"
+
+const defaultUSBLPTimeout = 5 * time.Second
+
+// usblpConn talks to the MSD347 over the kernel's usblp driver at
+// /dev/usblpN, using poll(2) to implement read/write timeouts instead
+// of depending on libusb.
+type usblpConn struct {
+	f *os.File
+
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+// DiscoverUSBLPDevices scans /dev/usblp* and returns the paths of
+// devices whose IEEE-1284 device ID string matches the MSD347.
+func DiscoverUSBLPDevices() ([]string, error) {
+	paths, err := filepath.Glob("/dev/usblp*")
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, path := range paths {
+		ok, err := usblpDeviceMatches(path)
+		if err != nil {
+			continue
+		}
+		if ok {
+			matches = append(matches, path)
+		}
+	}
+
+	return matches, nil
+}
+
+func usblpDeviceMatches(path string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	id, err := usblpDeviceID(f)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Contains(id, []byte(usblpDeviceIDSubstr)), nil
+}
+
+// usblpDeviceID issues LPIOC_GET_DEVICE_ID(len) (linux/lp.h) and
+// returns the device's IEEE-1284 ID string.
+func usblpDeviceID(f *os.File) ([]byte, error) {
+	// The kernel overwrites the first two bytes of the buffer with the
+	// big-endian length of the ID string that follows.
+	buf := make([]byte, 1024)
+
+	req := lpiocGetDeviceID(len(buf))
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), req, uintptr(unsafe.Pointer(&buf[0]))); errno != 0 {
+		return nil, fmt.Errorf("usblp: get device id: %s", errno)
+	}
+
+	// The length the kernel writes counts the 2-byte header itself, so
+	// the ID string is n-2 bytes starting at buf[2].
+	n := int(buf[0])<<8 | int(buf[1])
+	if n < 2 {
+		return nil, fmt.Errorf("usblp: get device id: length %d shorter than header", n)
+	}
+	if n > len(buf) {
+		n = len(buf)
+	}
+
+	return buf[2:n], nil
+}
+
+// lpiocGetDeviceID reproduces the LPIOC_GET_DEVICE_ID(len) macro from
+// linux/lp.h: _IOC(_IOC_READ, 'P', 1, len).
+func lpiocGetDeviceID(len int) uintptr {
+	const (
+		iocRead = 2
+		iocType = 'P'
+		iocNr   = 1
+	)
+	return uintptr(iocRead<<30 | (len&0x3fff)<<16 | iocType<<8 | iocNr)
+}
+
+func connectUSBLP(path string) (*usblpConn, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usblpConn{
+		f:            f,
+		readTimeout:  defaultUSBLPTimeout,
+		writeTimeout: defaultUSBLPTimeout,
+	}, nil
+}
+
+func (c *usblpConn) Read(buf []byte) (int, error) {
+	if err := c.poll(unix.POLLIN, c.readTimeout); err != nil {
+		return 0, err
+	}
+	return c.f.Read(buf)
+}
+
+func (c *usblpConn) Write(buf []byte) (int, error) {
+	if err := c.poll(unix.POLLOUT, c.writeTimeout); err != nil {
+		return 0, err
+	}
+	return c.f.Write(buf)
+}
+
+func (c *usblpConn) Close() error {
+	return c.f.Close()
+}
+
+func (c *usblpConn) poll(events int16, timeout time.Duration) error {
+	fds := []unix.PollFd{{Fd: int32(c.f.Fd()), Events: events}}
+
+	n, err := unix.Poll(fds, int(timeout/time.Millisecond))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("usblp: timed out after %s", timeout)
+	}
+
+	return nil
+}
+
+func defaultTransport() (Transport, error) {
+	paths, err := DiscoverUSBLPDevices()
+	if err != nil {
+		return nil, fmt.Errorf("usblp: discover devices: %s", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("usblp: no MSD347 found on /dev/usblp*")
+	}
+
+	return connectUSBLP(paths[0])
+}
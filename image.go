@@ -0,0 +1,349 @@
+package msd347
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+)
+
+// defaultMaxDotWidth is the printable dot width assumed for a 58mm
+// head when the caller doesn't override it with WithMaxDotWidth.
+const defaultMaxDotWidth = 512
+
+// defaultChunkSize is the raster write chunk size used when the
+// transport doesn't implement PacketSizer.
+const defaultChunkSize = 64
+
+// Dither selects how PrintImage reduces a color/grayscale image to the
+// printer's 1-bit raster format.
+type Dither int
+
+const (
+	DitherFloydSteinberg Dither = iota
+	DitherAtkinson
+	DitherOrderedBayer
+	DitherThreshold
+)
+
+// RasterMode selects the ESC/POS command family PrintImage uses to
+// send raster data.
+type RasterMode int
+
+const (
+	// RasterModeBlock sends the whole image as one GS v 0 block. It's
+	// the simplest path and what most firmware optimizes for.
+	RasterModeBlock RasterMode = iota
+	// RasterModeColumn8 sends the image in 8-dot passes via ESC * m=0,
+	// which some firmware/models print faster than a single GS v 0
+	// block.
+	RasterModeColumn8
+	// RasterModeColumn24 sends the image in 24-dot passes via
+	// ESC * m=32, trading the extra complexity of interleaving three
+	// bytes per column for a third as many passes as RasterModeColumn8.
+	RasterModeColumn24
+)
+
+// ImageOption configures PrintImage.
+type ImageOption func(*imageOptions)
+
+type imageOptions struct {
+	dither Dither
+	raster RasterMode
+}
+
+// WithDither selects the dithering algorithm PrintImage uses. The
+// default is Floyd-Steinberg, which isn't a good fit for line art and
+// logos; DitherThreshold or DitherOrderedBayer usually look better for
+// those.
+func WithDither(d Dither) ImageOption {
+	return func(o *imageOptions) { o.dither = d }
+}
+
+// WithRasterMode selects between GS v 0 block mode (the default) and
+// 8- or 24-dot ESC * column mode.
+func WithRasterMode(r RasterMode) ImageOption {
+	return func(o *imageOptions) { o.raster = r }
+}
+
+// PrintImage dithers img to 1-bit and prints it at the given print
+// mode. Width is rounded up to a whole byte (the trailing bits are
+// zero-padded) and clipped against the printer's configured max dot
+// width (see WithMaxDotWidth), not silently truncated.
+//
+// PrintImage can block waiting for the printer to report ready between
+// write chunks (see writeChunked); ctx bounds that wait the same way it
+// bounds WaitReady.
+func (p *Printer) PrintImage(ctx context.Context, img image.Image, m PrintMode, opts ...ImageOption) error {
+	o := imageOptions{dither: DitherFloydSteinberg, raster: RasterModeBlock}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	width := img.Bounds().Dx()
+	height := img.Bounds().Dy()
+
+	maxDotWidth := p.maxDotWidth
+	if maxDotWidth <= 0 {
+		maxDotWidth = defaultMaxDotWidth
+	}
+	if width > maxDotWidth {
+		return fmt.Errorf("width %d > max %d dots", width, maxDotWidth)
+	}
+	if height > 4095 {
+		return fmt.Errorf("height %d > max 4095 dots", height)
+	}
+
+	bits := ditherToBits(img, o.dither)
+
+	switch o.raster {
+	case RasterModeColumn8:
+		return p.printImageColumn(ctx, bits, width, height, 8)
+	case RasterModeColumn24:
+		return p.printImageColumn(ctx, bits, width, height, 24)
+	default:
+		return p.printImageBlock(ctx, bits, width, height, m)
+	}
+}
+
+func (p *Printer) printImageBlock(ctx context.Context, bits []bool, width, height int, m PrintMode) error {
+	bytesW := (width + 7) / 8
+	bytesH := height
+
+	if bytesW > 0xFFFF || bytesH > 0xFFFF {
+		return fmt.Errorf("image %dx%d dots too large for GS v 0", width, height)
+	}
+
+	data := make([]byte, bytesW*bytesH)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !bits[y*width+x] {
+				continue
+			}
+			data[y*bytesW+x/8] |= 1 << uint(7-x%8)
+		}
+	}
+
+	cmd := []byte{
+		GS, 'v', '0', byte(m),
+		byte(bytesW & 0xFF), byte((bytesW >> 8) & 0xFF),
+		byte(bytesH & 0xFF), byte((bytesH >> 8) & 0xFF),
+	}
+	cmd = append(cmd, data...)
+
+	return p.writeChunked(ctx, cmd)
+}
+
+// columnModeM maps a column-mode dots-per-pass count to the ESC * m
+// selector: m=0 for 8-dot single density, m=32 for 24-dot single
+// density.
+func columnModeM(dotsPerPass int) byte {
+	if dotsPerPass == 24 {
+		return 32
+	}
+	return 0
+}
+
+// printImageColumn sends the image as a series of ESC * m column
+// passes, dotsPerPass dots tall (8 or 24), instead of one GS v 0 block.
+// Line spacing is tightened to dotsPerPass dots for the duration so
+// consecutive passes butt up against each other, then restored.
+func (p *Printer) printImageColumn(ctx context.Context, bits []bool, width, height, dotsPerPass int) error {
+	bytesPerCol := dotsPerPass / 8
+	m := columnModeM(dotsPerPass)
+
+	nL := byte(width & 0xFF)
+	nH := byte((width >> 8) & 0xFF)
+
+	if err := p.writeLocked([]byte{ESC, '3', byte(dotsPerPass)}); err != nil {
+		return err
+	}
+	defer p.writeLocked([]byte{ESC, '2'})
+
+	for y := 0; y < height; y += dotsPerPass {
+		data := make([]byte, width*bytesPerCol)
+		for x := 0; x < width; x++ {
+			for b := 0; b < bytesPerCol; b++ {
+				var col byte
+				for bit := 0; bit < 8; bit++ {
+					py := y + b*8 + bit
+					if py >= height || !bits[py*width+x] {
+						continue
+					}
+					col |= 1 << uint(7-bit)
+				}
+				data[x*bytesPerCol+b] = col
+			}
+		}
+
+		cmd := []byte{ESC, '*', m, nL, nH}
+		cmd = append(cmd, data...)
+		cmd = append(cmd, '\n')
+
+		if err := p.writeChunked(ctx, cmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeLocked writes data to the printer under statusMu, the same lock
+// queryStatus takes around its own write+read, so the background
+// status-polling goroutine can't interleave a DLE EOT query in the
+// middle of it on the wire.
+func (p *Printer) writeLocked(data []byte) error {
+	p.statusMu.Lock()
+	defer p.statusMu.Unlock()
+
+	_, err := p.conn.Write(data)
+	return err
+}
+
+// writeChunked writes data to the printer in writeChunkSize pieces,
+// waiting for the printer to report ready between each one so a large
+// image doesn't outrun its print buffer, or returning early if ctx is
+// canceled first. Each chunk write goes through writeLocked so the
+// background status-polling goroutine can't interleave a DLE EOT query
+// in the middle of a raster chunk.
+func (p *Printer) writeChunked(ctx context.Context, data []byte) error {
+	chunkSize := p.writeChunkSize()
+	buf := bytes.NewBuffer(data)
+
+	for buf.Len() > 0 {
+		if err := p.WaitReady(ctx); err != nil {
+			return err
+		}
+
+		n := chunkSize
+		if buf.Len() < n {
+			n = buf.Len()
+		}
+
+		p.statusMu.Lock()
+		_, err := io.CopyN(p.conn, buf, int64(n))
+		p.statusMu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Printer) writeChunkSize() int {
+	if ps, ok := p.conn.(PacketSizer); ok {
+		if n := ps.MaxPacketSize(); n > 0 {
+			return n
+		}
+	}
+	return defaultChunkSize
+}
+
+func ditherToBits(img image.Image, d Dither) []bool {
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+
+	switch d {
+	case DitherAtkinson:
+		return ditherAtkinson(img, bounds, width, height)
+	case DitherOrderedBayer:
+		return ditherOrderedBayer(img, bounds, width, height)
+	case DitherThreshold:
+		return ditherThreshold(img, bounds, width, height)
+	default:
+		return ditherFloydSteinberg(img, bounds, width, height)
+	}
+}
+
+func ditherFloydSteinberg(img image.Image, bounds image.Rectangle, width, height int) []bool {
+	bw := image.NewPaletted(bounds, []color.Color{color.White, color.Black})
+	draw.FloydSteinberg.Draw(bw, bounds, img, bounds.Min)
+
+	bits := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			bits[y*width+x] = bw.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+y) > 0
+		}
+	}
+	return bits
+}
+
+func ditherThreshold(img image.Image, bounds image.Rectangle, width, height int) []bool {
+	bits := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			bits[y*width+x] = grayAt(img, bounds.Min.X+x, bounds.Min.Y+y) < 128
+		}
+	}
+	return bits
+}
+
+// bayer4x4 is a normalized 4x4 ordered-dithering threshold matrix.
+var bayer4x4 = [4][4]uint8{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+func ditherOrderedBayer(img image.Image, bounds image.Rectangle, width, height int) []bool {
+	bits := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			threshold := (uint16(bayer4x4[y%4][x%4]) + 1) * 256 / 17
+			bits[y*width+x] = uint16(grayAt(img, bounds.Min.X+x, bounds.Min.Y+y)) < threshold
+		}
+	}
+	return bits
+}
+
+// ditherAtkinson implements Bill Atkinson's dithering algorithm: each
+// pixel's quantization error is split 1/8th to each of six
+// neighbors, discarding the remaining 2/8ths so errors don't spread as
+// far as Floyd-Steinberg's.
+func ditherAtkinson(img image.Image, bounds image.Rectangle, width, height int) []bool {
+	gray := make([]int16, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			gray[y*width+x] = int16(grayAt(img, bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	offsets := [6][2]int{{1, 0}, {2, 0}, {-1, 1}, {0, 1}, {1, 1}, {0, 2}}
+
+	bits := make([]bool, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			old := gray[i]
+
+			black := old < 128
+			bits[i] = black
+
+			var quantized int16 = 255
+			if black {
+				quantized = 0
+			}
+			diffused := (old - quantized) / 8
+
+			for _, o := range offsets {
+				nx, ny := x+o[0], y+o[1]
+				if nx < 0 || nx >= width || ny >= height {
+					continue
+				}
+				gray[ny*width+nx] += diffused
+			}
+		}
+	}
+
+	return bits
+}
+
+func grayAt(img image.Image, x, y int) uint8 {
+	return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+}
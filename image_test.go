@@ -0,0 +1,69 @@
+package msd347
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// checkerboard returns a w x h grayscale image alternating black and
+// white pixels, which every dither mode should reduce to the same
+// pattern since there's no room for error diffusion to matter.
+func checkerboard(w, h int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.Gray{Y: 255}
+			if (x+y)%2 == 0 {
+				c = color.Gray{Y: 0}
+			}
+			img.SetGray(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestDitherThreshold(t *testing.T) {
+	img := checkerboard(4, 2)
+	bits := ditherToBits(img, DitherThreshold)
+
+	want := []bool{true, false, true, false, false, true, false, true}
+	for i := range want {
+		if bits[i] != want[i] {
+			t.Errorf("bit %d = %v, want %v", i, bits[i], want[i])
+		}
+	}
+}
+
+func TestDitherModesProduceRightSizedBits(t *testing.T) {
+	img := checkerboard(6, 4)
+
+	for _, d := range []Dither{DitherFloydSteinberg, DitherAtkinson, DitherOrderedBayer, DitherThreshold} {
+		bits := ditherToBits(img, d)
+		if len(bits) != 6*4 {
+			t.Errorf("dither mode %v: len(bits) = %d, want %d", d, len(bits), 6*4)
+		}
+	}
+}
+
+func TestDitherAllBlackAllWhite(t *testing.T) {
+	black := image.NewGray(image.Rect(0, 0, 3, 3))
+	white := image.NewGray(image.Rect(0, 0, 3, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			black.SetGray(x, y, color.Gray{Y: 0})
+			white.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+
+	for _, bit := range ditherToBits(black, DitherFloydSteinberg) {
+		if !bit {
+			t.Errorf("all-black image dithered to a white pixel")
+		}
+	}
+	for _, bit := range ditherToBits(white, DitherFloydSteinberg) {
+		if bit {
+			t.Errorf("all-white image dithered to a black pixel")
+		}
+	}
+}
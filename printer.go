@@ -1,15 +1,6 @@
 package msd347
 
-import (
-	"bytes"
-	"errors"
-	"fmt"
-	"image"
-	"image/color"
-	"image/draw"
-	"io"
-	"sync"
-)
+import "sync"
 
 const (
 	ESC byte = 27
@@ -27,22 +18,6 @@ const (
 	JustifyRight  Justification = 2
 )
 
-const (
-	Status0 = 1 << iota
-	Status2
-	TicketTaken
-)
-
-const (
-	ErrorStatus0 = 1 << iota
-	ErrorStatus1
-	ErrorStatusMechanical
-	ErrorStatusAutocutter
-	ErrorStatus4
-	ErrorStatusUnrecoverable
-	ErrorStatusAutorecoverable
-)
-
 type PrintMode byte
 
 const (
@@ -52,35 +27,61 @@ const (
 	PrintQuadruple    PrintMode = 3
 )
 
-type ErrorInfo struct {
-	MechanicalError      bool
-	AutocutterError      bool
-	UnrecoverableError   bool
-	AutorecoverableError bool
-}
+func Connect(opts ...Option) (*Printer, error) {
+	var o connectOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-type TicketInfo struct {
-	TicketTaken bool
-}
+	conn := o.transport
+	if conn == nil {
+		c, err := defaultTransport()
+		if err != nil {
+			return nil, err
+		}
+		conn = c
+	}
+
+	caps := o.caps
+	if caps == nil {
+		caps = probePrinterCaps(conn)
+	}
 
-func Connect() (*Printer, error) {
-	conn, err := connectUSB()
-	if err != nil {
-		return nil, err
+	maxDotWidth := o.maxDotWidth
+	if maxDotWidth <= 0 {
+		maxDotWidth = defaultMaxDotWidth
 	}
 
 	return &Printer{
-		conn: conn,
+		conn:        conn,
+		caps:        caps,
+		maxDotWidth: maxDotWidth,
 	}, nil
 }
 
 type Printer struct {
-	conn io.ReadWriteCloser
+	conn        Transport
+	caps        *PrinterCaps
+	maxDotWidth int
 
 	statusMu sync.Mutex
+
+	statusOnce sync.Once
+	statusDone chan struct{}
+
+	subMu      sync.Mutex
+	subs       []chan StatusEvent
+	lastStatus Status
+	haveStatus bool
 }
 
 func (p *Printer) Close() error {
+	p.subMu.Lock()
+	if p.statusDone != nil {
+		close(p.statusDone)
+	}
+	p.subMu.Unlock()
+
 	return p.conn.Close()
 }
 
@@ -108,61 +109,6 @@ func (p *Printer) SetButtonsEnabled(enabled bool) error {
 	return nil
 }
 
-func (p *Printer) PrintImage(img image.Image, m PrintMode) error {
-	width := img.Bounds().Dx()
-	height := img.Bounds().Dy()
-
-	// TODO(maxhawkins): deal with non power of two textures
-	bytesW := width / 8
-	bytesH := height
-
-	bwImg := image.NewPaletted(img.Bounds(), []color.Color{color.White, color.Black})
-	draw.FloydSteinberg.Draw(bwImg, img.Bounds(), img, image.ZP)
-	// draw.Draw(bwImg, img.Bounds(), img, image.ZP, draw.Over)
-
-	if bytesW > 128 {
-		return fmt.Errorf("width %d > max 128 bytes (1024 dots)", bytesW)
-	}
-	if bytesH > 4095 {
-		return fmt.Errorf("height %d > max 4095 bytes (4095 dots)", bytesH)
-	}
-
-	widthL := byte(bytesW & 0xFF)
-	widthH := byte((bytesW >> 8) & 0xFF)
-
-	heightL := byte(bytesH & 0xFF)
-	heightH := byte((bytesH >> 8) & 0xFF)
-
-	data := make([]byte, bytesW*bytesH)
-	for x := 0; x < width; x++ {
-		for y := 0; y < height; y++ {
-			i := x/8 + bytesW*y
-			mask := uint8(1) << uint8(7-x%8)
-
-			px := bwImg.ColorIndexAt(x, y)
-			if px > 0 {
-				data[i] |= mask
-			}
-		}
-	}
-
-	cmd := []byte{GS, 'v', '0', byte(m), widthL, widthH, heightL, heightH}
-	cmd = append(cmd, data...)
-	buf := bytes.NewBuffer(cmd)
-
-	for {
-		_, err := io.CopyN(p.conn, buf, 64)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (p *Printer) SetJustification(j Justification) error {
 	cmd := []byte{ESC, 'a', byte(j)}
 
@@ -182,88 +128,3 @@ func (p *Printer) FullCut() error {
 
 	return nil
 }
-
-func (e ErrorInfo) Error() string {
-	var s string
-
-	if e.MechanicalError {
-		s = "mechanical error"
-	} else if e.AutocutterError {
-		s = "autocutter error"
-	} else {
-		s = "printer error"
-	}
-
-	if e.UnrecoverableError {
-		s += " (unrecoverable)"
-	} else if e.AutorecoverableError {
-		s += " (recoverable)"
-	}
-
-	return s
-}
-
-func (e ErrorInfo) OK() bool {
-	return !(e.MechanicalError ||
-		e.AutocutterError ||
-		e.UnrecoverableError ||
-		e.AutocutterError)
-}
-
-func (p *Printer) QueryErr() error {
-	p.statusMu.Lock()
-	defer p.statusMu.Unlock()
-
-	cmd := []byte{DLE, EOT, 3}
-	if _, err := p.conn.Write(cmd); err != nil {
-		return err
-	}
-
-	buf := make([]byte, 1)
-	i, err := p.conn.Read(buf)
-	if err != nil {
-		return err
-	}
-	if i < 1 {
-		return errors.New("query error: expected to read byte")
-	}
-	val := buf[0]
-
-	info := &ErrorInfo{
-		MechanicalError:      (val&ErrorStatusMechanical == 1),
-		AutocutterError:      (val&ErrorStatusAutocutter == 1),
-		UnrecoverableError:   (val&ErrorStatusUnrecoverable == 1),
-		AutorecoverableError: (val&ErrorStatusAutorecoverable == 1),
-	}
-	if info.OK() {
-		return nil
-	}
-
-	return info
-}
-
-func (p *Printer) GetTicketInfo() (TicketInfo, error) {
-	var info TicketInfo
-
-	p.statusMu.Lock()
-	defer p.statusMu.Unlock()
-
-	cmd := []byte{DLE, EOT, 5}
-	if _, err := p.conn.Write(cmd); err != nil {
-		return info, err
-	}
-
-	buf := make([]byte, 1)
-	i, err := p.conn.Read(buf)
-	if err != nil {
-		return info, err
-	}
-	if i < 1 {
-		return info, errors.New("ticket info: expected to read byte")
-	}
-	val := buf[0]
-
-	info.TicketTaken = (val&TicketTaken == 0)
-
-	return info, nil
-}
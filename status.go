@@ -0,0 +1,273 @@
+package msd347
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Bit layout for the n=1..4 DLE EOT transmit-status queries, per the
+// MSD347's ESC/POS programming manual.
+const (
+	printerStatusOffline = 1 << 3
+)
+
+const (
+	offlineCauseCoverOpen       = 1 << 2
+	offlineCausePaperFeedSwitch = 1 << 3
+	offlineCauseError           = 1 << 5
+)
+
+const (
+	ErrorStatusMechanical      = 1 << 2
+	ErrorStatusAutocutter      = 1 << 3
+	ErrorStatusUnrecoverable   = 1 << 5
+	ErrorStatusAutorecoverable = 1 << 6
+)
+
+const (
+	paperSensorNearEnd = 1 << 2
+	paperSensorEnd     = 1 << 5
+)
+
+// ticketTakenBit is the MSD347's vendor-specific n=5 query: it reads 0
+// once the printed ticket has been removed from the presenter.
+const ticketTakenBit = 1 << 2
+
+// defaultStatusPollInterval is how often Printer polls DLE EOT n=1..5
+// once status polling has started.
+const defaultStatusPollInterval = 200 * time.Millisecond
+
+// Status is a snapshot of the printer's real-time status, assembled
+// from the four ASB queries (DLE EOT n=1..4) plus the MSD347's
+// vendor-specific ticket-taken query (n=5).
+type Status struct {
+	Offline bool
+
+	CoverOpen        bool
+	PaperFeedPressed bool
+	// OfflineCause reports that the printer went offline because of an
+	// error, as opposed to a cover-open or paper-feed-button offline.
+	OfflineCause bool
+
+	RecoverableError   bool
+	UnrecoverableError bool
+	AutocutterError    bool
+	MechanicalError    bool
+
+	NearEnd  bool
+	PaperEnd bool
+
+	TicketTaken bool
+}
+
+// StatusEvent is delivered to subscribers whenever a Status poll
+// produces a value that differs from the previous one.
+type StatusEvent struct {
+	Status Status
+	Time   time.Time
+}
+
+func decodeStatus(n1, n2, n3, n4, n5 byte) Status {
+	return Status{
+		Offline: n1&printerStatusOffline != 0,
+
+		CoverOpen:        n2&offlineCauseCoverOpen != 0,
+		PaperFeedPressed: n2&offlineCausePaperFeedSwitch != 0,
+		OfflineCause:     n2&offlineCauseError != 0,
+
+		RecoverableError:   n3&ErrorStatusAutorecoverable != 0,
+		UnrecoverableError: n3&ErrorStatusUnrecoverable != 0,
+		AutocutterError:    n3&ErrorStatusAutocutter != 0,
+		MechanicalError:    n3&ErrorStatusMechanical != 0,
+
+		NearEnd:  n4&paperSensorNearEnd != 0,
+		PaperEnd: n4&paperSensorEnd != 0,
+
+		TicketTaken: n5&ticketTakenBit == 0,
+	}
+}
+
+// Ready reports whether the printer can currently accept commands: no
+// door open, no paper problem, and no error condition.
+//
+// Ready does not track the print buffer's fill level: the MSD347's
+// programming manual documents no ASB bit for "buffer draining", and
+// DLE EOT only defines n=1..5 (n=5 itself already a vendor extension).
+// Buffer-level pacing instead falls out of the transport: both
+// conn_usblp_linux.go (blocking poll(2) writes) and conn_libusb.go
+// (blocking bulk transfers) block a Write call until the device's USB
+// endpoint accepts the data, so writeChunked's chunking already can't
+// outrun the printer without needing a polled status bit for it.
+func (s Status) Ready() bool {
+	return !s.Offline &&
+		!s.MechanicalError &&
+		!s.AutocutterError &&
+		!s.UnrecoverableError &&
+		!s.PaperEnd
+}
+
+// Subscribe starts the status-polling goroutine if it isn't already
+// running and returns a channel of StatusEvents plus a function to stop
+// receiving them. Callers must call the returned function once they're
+// done to release the channel.
+func (p *Printer) Subscribe() (<-chan StatusEvent, func()) {
+	p.ensureStatusPolling()
+
+	ch := make(chan StatusEvent, 1)
+
+	p.subMu.Lock()
+	p.subs = append(p.subs, ch)
+	p.subMu.Unlock()
+
+	unsubscribe := func() {
+		p.subMu.Lock()
+		defer p.subMu.Unlock()
+
+		for i, c := range p.subs {
+			if c == ch {
+				p.subs = append(p.subs[:i], p.subs[i+1:]...)
+				close(ch)
+				break
+			}
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// WaitTicketTaken blocks until the printer reports the printed ticket
+// has been removed, or ctx is canceled.
+func (p *Printer) WaitTicketTaken(ctx context.Context) error {
+	return p.waitFor(ctx, func(s Status) bool { return s.TicketTaken })
+}
+
+// WaitReady blocks until the printer reports no offline, paper, or
+// error condition, or ctx is canceled.
+func (p *Printer) WaitReady(ctx context.Context) error {
+	return p.waitFor(ctx, Status.Ready)
+}
+
+func (p *Printer) waitFor(ctx context.Context, ready func(Status) bool) error {
+	p.ensureStatusPolling()
+
+	p.subMu.Lock()
+	cur, have := p.lastStatus, p.haveStatus
+	p.subMu.Unlock()
+
+	if have && ready(cur) {
+		return nil
+	}
+
+	ch, unsubscribe := p.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev := <-ch:
+			if ready(ev.Status) {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *Printer) ensureStatusPolling() {
+	p.statusOnce.Do(func() {
+		p.subMu.Lock()
+		p.statusDone = make(chan struct{})
+		p.subMu.Unlock()
+
+		go p.pollStatusLoop()
+	})
+}
+
+func (p *Printer) pollStatusLoop() {
+	ticker := time.NewTicker(defaultStatusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.statusDone:
+			return
+		case <-ticker.C:
+			st, err := p.queryStatus()
+			if err != nil {
+				continue
+			}
+			p.publishStatus(st)
+		}
+	}
+}
+
+func (p *Printer) publishStatus(st Status) {
+	p.subMu.Lock()
+	changed := !p.haveStatus || p.lastStatus != st
+	p.lastStatus = st
+	p.haveStatus = true
+	subs := append([]chan StatusEvent(nil), p.subs...)
+	p.subMu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	ev := StatusEvent{Status: st, Time: time.Now()}
+	for _, ch := range subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// queryStatus issues the n=1..5 DLE EOT transmit-status queries as a
+// single critical section, so a concurrent caller can't interleave an
+// unrelated write+read pair on the same wire.
+func (p *Printer) queryStatus() (Status, error) {
+	p.statusMu.Lock()
+	defer p.statusMu.Unlock()
+
+	n1, err := p.queryStatusByte(1)
+	if err != nil {
+		return Status{}, err
+	}
+	n2, err := p.queryStatusByte(2)
+	if err != nil {
+		return Status{}, err
+	}
+	n3, err := p.queryStatusByte(3)
+	if err != nil {
+		return Status{}, err
+	}
+	n4, err := p.queryStatusByte(4)
+	if err != nil {
+		return Status{}, err
+	}
+	n5, err := p.queryStatusByte(5)
+	if err != nil {
+		return Status{}, err
+	}
+
+	return decodeStatus(n1, n2, n3, n4, n5), nil
+}
+
+func (p *Printer) queryStatusByte(n byte) (byte, error) {
+	cmd := []byte{DLE, EOT, n}
+	if _, err := p.conn.Write(cmd); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1)
+	i, err := p.conn.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if i < 1 {
+		return 0, fmt.Errorf("status query n=%d: expected to read byte", n)
+	}
+
+	return buf[0], nil
+}
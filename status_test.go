@@ -0,0 +1,45 @@
+package msd347
+
+import "testing"
+
+func TestDecodeStatus(t *testing.T) {
+	st := decodeStatus(
+		printerStatusOffline,
+		offlineCauseCoverOpen,
+		ErrorStatusAutocutter,
+		paperSensorNearEnd,
+		0, // ticketTakenBit clear means TicketTaken
+	)
+
+	want := Status{
+		Offline:         true,
+		CoverOpen:       true,
+		AutocutterError: true,
+		NearEnd:         true,
+		TicketTaken:     true,
+	}
+	if st != want {
+		t.Fatalf("decodeStatus = %+v, want %+v", st, want)
+	}
+}
+
+func TestStatusReady(t *testing.T) {
+	cases := []struct {
+		name string
+		st   Status
+		want bool
+	}{
+		{"all clear", Status{}, true},
+		{"offline", Status{Offline: true}, false},
+		{"mechanical error", Status{MechanicalError: true}, false},
+		{"autocutter error", Status{AutocutterError: true}, false},
+		{"unrecoverable error", Status{UnrecoverableError: true}, false},
+		{"paper end", Status{PaperEnd: true}, false},
+	}
+
+	for _, c := range cases {
+		if got := c.st.Ready(); got != c.want {
+			t.Errorf("%s: Ready() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
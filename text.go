@@ -0,0 +1,77 @@
+package msd347
+
+import "fmt"
+
+// Font selects between the printer's two built-in character sets
+// (ESC M).
+type Font byte
+
+const (
+	FontA Font = 0
+	FontB Font = 1
+)
+
+// SetBold turns character emphasis on or off (ESC E).
+func (p *Printer) SetBold(enabled bool) error {
+	_, err := p.conn.Write([]byte{ESC, 'E', boolByte(enabled)})
+	return err
+}
+
+// SetUnderline turns underlining on or off (ESC -).
+func (p *Printer) SetUnderline(enabled bool) error {
+	_, err := p.conn.Write([]byte{ESC, '-', boolByte(enabled)})
+	return err
+}
+
+// SetInverted turns black/white reverse printing on or off (GS B).
+func (p *Printer) SetInverted(enabled bool) error {
+	_, err := p.conn.Write([]byte{GS, 'B', boolByte(enabled)})
+	return err
+}
+
+// SetFont selects font A or B (ESC M).
+func (p *Printer) SetFont(f Font) error {
+	_, err := p.conn.Write([]byte{ESC, 'M', byte(f)})
+	return err
+}
+
+// SetCharSize sets the character width and height multipliers, 1-8
+// each (GS !).
+func (p *Printer) SetCharSize(width, height int) error {
+	if width < 1 || width > 8 {
+		return fmt.Errorf("char width %d out of range [1,8]", width)
+	}
+	if height < 1 || height > 8 {
+		return fmt.Errorf("char height %d out of range [1,8]", height)
+	}
+
+	n := byte((width-1)<<4 | (height - 1))
+	_, err := p.conn.Write([]byte{GS, '!', n})
+	return err
+}
+
+// Feed advances the paper by n lines (ESC d).
+func (p *Printer) Feed(n int) error {
+	if n < 0 || n > 255 {
+		return fmt.Errorf("feed lines %d out of range [0,255]", n)
+	}
+
+	_, err := p.conn.Write([]byte{ESC, 'd', byte(n)})
+	return err
+}
+
+// WriteRaw writes b directly to the printer. It's an escape hatch for
+// callers (notably the ticket package) that need to send already
+// code-page-encoded text or a vendor command with no dedicated Printer
+// method.
+func (p *Printer) WriteRaw(b []byte) error {
+	_, err := p.conn.Write(b)
+	return err
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,249 @@
+// Package ticket provides a high-level, declarative way to build a
+// receipt out of text, images, barcodes, and cut markers, and render it
+// to a printer in one call. It depends only on msd347's low-level
+// Printer API.
+package ticket
+
+import (
+	"context"
+	"image"
+
+	msd347 "github.com/maxhawkins/msd347"
+)
+
+// Font selects between the printer's two built-in character sets.
+type Font = msd347.Font
+
+const (
+	FontA = msd347.FontA
+	FontB = msd347.FontB
+)
+
+// TextAttr describes how a run of text should be printed.
+type TextAttr struct {
+	Bold      bool
+	Underline bool
+	Invert    bool
+	Font      Font
+
+	// Width and Height are character size multipliers, 1-8. Zero
+	// means 1 (normal size).
+	Width  int
+	Height int
+}
+
+type node interface {
+	render(p *msd347.Printer, cp *CodePage) error
+}
+
+// Document accumulates a sequence of nodes and renders them to a
+// printer in one Render call.
+type Document struct {
+	codepage *CodePage
+	nodes    []node
+}
+
+// New creates an empty Document that encodes text using cp. A nil cp
+// defaults to CP437.
+func New(cp *CodePage) *Document {
+	if cp == nil {
+		cp = CP437
+	}
+	return &Document{codepage: cp}
+}
+
+// Text appends a run of text with the given attributes, written as-is
+// with no wrapping or trailing newline.
+func (d *Document) Text(s string, attr TextAttr) *Document {
+	d.nodes = append(d.nodes, textNode{text: s, attr: attr})
+	return d
+}
+
+// Paragraph appends word-wrapped text, justified as j, with a trailing
+// newline after each wrapped line. The wrap width is derived from
+// attr's font and character width via Columns.
+func (d *Document) Paragraph(s string, j msd347.Justification, attr TextAttr) *Document {
+	d.nodes = append(d.nodes, paragraphNode{text: s, justify: j, attr: attr})
+	return d
+}
+
+// Rule appends a horizontal line of the given width made of r
+// (typically '-' or '='). A width of 0 uses the font A normal-width
+// column count.
+func (d *Document) Rule(r byte, width int) *Document {
+	d.nodes = append(d.nodes, ruleNode{r: r, width: width})
+	return d
+}
+
+// Feed appends a paper feed of n lines.
+func (d *Document) Feed(n int) *Document {
+	d.nodes = append(d.nodes, feedNode{lines: n})
+	return d
+}
+
+// Image appends a raster image.
+func (d *Document) Image(img image.Image, mode msd347.PrintMode) *Document {
+	d.nodes = append(d.nodes, imageNode{img: img, mode: mode})
+	return d
+}
+
+// Barcode appends a barcode.
+func (d *Document) Barcode(sym msd347.BarcodeSymbology, data string, opts msd347.BarcodeOptions) *Document {
+	d.nodes = append(d.nodes, barcodeNode{sym: sym, data: data, opts: opts})
+	return d
+}
+
+// QR appends a QR code.
+func (d *Document) QR(data string, opts msd347.QROptions) *Document {
+	d.nodes = append(d.nodes, qrNode{data: data, opts: opts})
+	return d
+}
+
+// Cut appends a full cut.
+func (d *Document) Cut() *Document {
+	d.nodes = append(d.nodes, cutNode{})
+	return d
+}
+
+// Render selects the Document's code page and sends every accumulated
+// node to p in order.
+func (d *Document) Render(p *msd347.Printer) error {
+	if err := p.WriteRaw(d.codepage.SelectCommand()); err != nil {
+		return err
+	}
+
+	for _, n := range d.nodes {
+		if err := n.render(p, d.codepage); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type textNode struct {
+	text string
+	attr TextAttr
+}
+
+func (n textNode) render(p *msd347.Printer, cp *CodePage) error {
+	return writeText(p, cp, n.text, n.attr)
+}
+
+type paragraphNode struct {
+	text    string
+	justify msd347.Justification
+	attr    TextAttr
+}
+
+func (n paragraphNode) render(p *msd347.Printer, cp *CodePage) error {
+	if err := p.SetJustification(n.justify); err != nil {
+		return err
+	}
+
+	width := n.attr.Width
+	if width < 1 {
+		width = 1
+	}
+
+	for _, line := range WrapText(n.text, Columns(n.attr.Font, width)) {
+		if err := writeText(p, cp, line+"\n", n.attr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type ruleNode struct {
+	r     byte
+	width int
+}
+
+func (n ruleNode) render(p *msd347.Printer, cp *CodePage) error {
+	width := n.width
+	if width < 1 {
+		width = Columns(FontA, 1)
+	}
+
+	line := make([]byte, width+1)
+	for i := 0; i < width; i++ {
+		line[i] = n.r
+	}
+	line[width] = '\n'
+
+	return p.WriteRaw(line)
+}
+
+type feedNode struct{ lines int }
+
+func (n feedNode) render(p *msd347.Printer, cp *CodePage) error {
+	return p.Feed(n.lines)
+}
+
+type imageNode struct {
+	img  image.Image
+	mode msd347.PrintMode
+}
+
+func (n imageNode) render(p *msd347.Printer, cp *CodePage) error {
+	return p.PrintImage(context.Background(), n.img, n.mode)
+}
+
+type barcodeNode struct {
+	sym  msd347.BarcodeSymbology
+	data string
+	opts msd347.BarcodeOptions
+}
+
+func (n barcodeNode) render(p *msd347.Printer, cp *CodePage) error {
+	return p.PrintBarcode(n.sym, n.data, n.opts)
+}
+
+type qrNode struct {
+	data string
+	opts msd347.QROptions
+}
+
+func (n qrNode) render(p *msd347.Printer, cp *CodePage) error {
+	return p.PrintQR(n.data, n.opts)
+}
+
+type cutNode struct{}
+
+func (cutNode) render(p *msd347.Printer, cp *CodePage) error {
+	return p.FullCut()
+}
+
+func writeText(p *msd347.Printer, cp *CodePage, text string, attr TextAttr) error {
+	if err := p.SetBold(attr.Bold); err != nil {
+		return err
+	}
+	if err := p.SetUnderline(attr.Underline); err != nil {
+		return err
+	}
+	if err := p.SetInverted(attr.Invert); err != nil {
+		return err
+	}
+	if err := p.SetFont(attr.Font); err != nil {
+		return err
+	}
+
+	width, height := attr.Width, attr.Height
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	if err := p.SetCharSize(width, height); err != nil {
+		return err
+	}
+
+	encoded, err := cp.Encode(text)
+	if err != nil {
+		return err
+	}
+
+	return p.WriteRaw(encoded)
+}
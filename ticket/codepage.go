@@ -0,0 +1,157 @@
+package ticket
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Replacement is the rune substituted for unrepresentable characters
+// by CodePage.Encode.
+const Replacement = '?'
+
+// CodePage transcodes UTF-8 text into the 8-bit table a printer's
+// ESC t command selects. Bytes 0x00-0x7F are standard ASCII in every
+// table below; only the upper 128 code points vary, and only the
+// accented Latin letters commonly seen on receipts are mapped here —
+// extend the table literals if you need fuller coverage.
+type CodePage struct {
+	name   string
+	escT   byte
+	toByte map[rune]byte
+}
+
+func (cp *CodePage) String() string { return cp.name }
+
+// SelectCommand returns the ESC t n command that selects this code
+// page on the printer.
+func (cp *CodePage) SelectCommand() []byte {
+	return []byte{0x1B, 't', cp.escT}
+}
+
+// Encode transcodes s into the code page's 8-bit representation,
+// substituting Replacement for runes the table can't express.
+func (cp *CodePage) Encode(s string) ([]byte, error) {
+	return cp.EncodeWithReplacement(s, Replacement)
+}
+
+// EncodeWithReplacement is like Encode, but substitutes r for
+// unrepresentable runes. If r is negative, an unrepresentable rune
+// returns an error instead of being substituted.
+func (cp *CodePage) EncodeWithReplacement(s string, r rune) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+
+	for _, c := range s {
+		if c < utf8.RuneSelf {
+			out = append(out, byte(c))
+			continue
+		}
+
+		b, ok := cp.toByte[c]
+		if !ok {
+			if r < 0 {
+				return nil, fmt.Errorf("codepage %s: rune %q has no mapping", cp.name, c)
+			}
+			b, ok = cp.toByte[r]
+			if !ok {
+				b = byte(r)
+			}
+		}
+
+		out = append(out, b)
+	}
+
+	return out, nil
+}
+
+// CP437 is IBM PC code page 437 (US English).
+var CP437 = &CodePage{
+	name: "CP437",
+	escT: 0,
+	toByte: map[rune]byte{
+		'ü': 0x81, 'é': 0x82, 'â': 0x83, 'ä': 0x84, 'à': 0x85, 'å': 0x86,
+		'ç': 0x87, 'ê': 0x88, 'ë': 0x89, 'è': 0x8A, 'ï': 0x8B, 'î': 0x8C,
+		'ì': 0x8D, 'Ä': 0x8E, 'Å': 0x8F, 'É': 0x90, 'ô': 0x93, 'ö': 0x94,
+		'ò': 0x95, 'û': 0x96, 'ù': 0x97, 'ÿ': 0x98, 'Ö': 0x99, 'Ü': 0x9A,
+		'¢': 0x9B, '£': 0x9C, '¥': 0x9D, '°': 0xF8, 'ñ': 0xA4, 'Ñ': 0xA5,
+	},
+}
+
+// CP850 is code page 850 (Western European).
+var CP850 = &CodePage{
+	name: "CP850",
+	escT: 2,
+	toByte: map[rune]byte{
+		'ü': 0x81, 'é': 0x82, 'â': 0x83, 'ä': 0x84, 'à': 0x85, 'å': 0x86,
+		'ç': 0x87, 'ê': 0x88, 'ë': 0x89, 'è': 0x8A, 'ï': 0x8B, 'î': 0x8C,
+		'ì': 0x8D, 'Ä': 0x8E, 'Å': 0x8F, 'É': 0x90, 'ô': 0x93, 'ö': 0x94,
+		'ò': 0x95, 'û': 0x96, 'ù': 0x97, 'ÿ': 0x98, 'Ö': 0x99, 'Ü': 0x9A,
+		'ñ': 0xA4, 'Ñ': 0xA5, '°': 0xF8, 'ß': 0xE1, 'µ': 0xE6,
+	},
+}
+
+// CP858 is code page 858 (Western European with the euro sign).
+var CP858 = &CodePage{
+	name: "CP858",
+	escT: 19,
+	toByte: map[rune]byte{
+		'ü': 0x81, 'é': 0x82, 'â': 0x83, 'ä': 0x84, 'à': 0x85, 'å': 0x86,
+		'ç': 0x87, 'ê': 0x88, 'ë': 0x89, 'è': 0x8A, 'ï': 0x8B, 'î': 0x8C,
+		'ì': 0x8D, 'Ä': 0x8E, 'Å': 0x8F, 'É': 0x90, 'ô': 0x93, 'ö': 0x94,
+		'ò': 0x95, 'û': 0x96, 'ù': 0x97, 'ÿ': 0x98, 'Ö': 0x99, 'Ü': 0x9A,
+		'ñ': 0xA4, 'Ñ': 0xA5, '°': 0xF8, 'ß': 0xE1, '€': 0xD5,
+	},
+}
+
+// Latin1 is ISO-8859-1, used by printers whose firmware exposes the
+// upper half as a direct Latin-1 passthrough.
+var Latin1 = &CodePage{
+	name: "Latin1",
+	escT: 255,
+	toByte: map[rune]byte{
+		'ü': 0xFC, 'é': 0xE9, 'â': 0xE2, 'ä': 0xE4, 'à': 0xE0, 'å': 0xE5,
+		'ç': 0xE7, 'ê': 0xEA, 'ë': 0xEB, 'è': 0xE8, 'ï': 0xEF, 'î': 0xEE,
+		'ì': 0xEC, 'Ä': 0xC4, 'Å': 0xC5, 'É': 0xC9, 'ô': 0xF4, 'ö': 0xF6,
+		'ò': 0xF2, 'û': 0xFB, 'ù': 0xF9, 'ÿ': 0xFF, 'Ö': 0xD6, 'Ü': 0xDC,
+		'ñ': 0xF1, 'Ñ': 0xD1, '°': 0xB0, 'ß': 0xDF, '£': 0xA3, '¥': 0xA5,
+	},
+}
+
+// PC852 is code page 852 (Central European).
+var PC852 = &CodePage{
+	name: "PC852",
+	escT: 18,
+	toByte: map[rune]byte{
+		'é': 0x82, 'ä': 0x84, 'ç': 0x87, 'ë': 0x89, 'ï': 0x8B, 'î': 0x8C,
+		'Ä': 0x8E, 'ô': 0x93, 'ö': 0x94, 'Ö': 0x99, 'Ü': 0x9A, 'ü': 0x81,
+		'ń': 0xA6, 'ą': 0xA4, 'ć': 0x86, 'ę': 0xA8, 'ł': 0x88, 'ś': 0xA5,
+		'ż': 0xA7, 'ź': 0xAB, 'ó': 0xA2, 'Ł': 0x9D, 'Ś': 0x98, 'Ż': 0xBE,
+	},
+}
+
+// PC866 is code page 866 (Cyrillic).
+var PC866 = &CodePage{
+	name: "PC866",
+	escT: 17,
+	toByte: map[rune]byte{
+		'а': 0xA0, 'б': 0xA1, 'в': 0xA2, 'г': 0xA3, 'д': 0xA4, 'е': 0xA5,
+		'ж': 0xA6, 'з': 0xA7, 'и': 0xA8, 'й': 0xA9, 'к': 0xAA, 'л': 0xAB,
+		'м': 0xAC, 'н': 0xAD, 'о': 0xAE, 'п': 0xAF, 'р': 0xE0, 'с': 0xE1,
+		'т': 0xE2, 'у': 0xE3, 'ф': 0xE4, 'х': 0xE5, 'ц': 0xE6, 'ч': 0xE7,
+		'ш': 0xE8, 'щ': 0xE9, 'ъ': 0xEA, 'ы': 0xEB, 'ь': 0xEC, 'э': 0xED,
+		'ю': 0xEE, 'я': 0xEF,
+	},
+}
+
+// Katakana is the printer's built-in JIS katakana table (commonly
+// code page 0 on "international" firmware, selected with a distinct
+// ESC t value from CP437's page 0).
+var Katakana = &CodePage{
+	name: "Katakana",
+	escT: 1,
+	toByte: map[rune]byte{
+		'ア': 0xB1, 'イ': 0xB2, 'ウ': 0xB3, 'エ': 0xB4, 'オ': 0xB5,
+		'カ': 0xB6, 'キ': 0xB7, 'ク': 0xB8, 'ケ': 0xB9, 'コ': 0xBA,
+		'サ': 0xBB, 'シ': 0xBC, 'ス': 0xBD, 'セ': 0xBE, 'ソ': 0xBF,
+		'ン': 0xDD, 'ー': 0xB0,
+	},
+}
@@ -0,0 +1,45 @@
+package ticket
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCodePageEncode(t *testing.T) {
+	got, err := CP437.Encode("café")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	want := []byte{'c', 'a', 'f', 0x82}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Encode(%q) = %v, want %v", "café", got, want)
+	}
+}
+
+func TestCodePageEncodeUnmappedRune(t *testing.T) {
+	got, err := CP437.Encode("ア?")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	want := []byte{'?', '?'}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Encode of an unmapped rune = %v, want %v", got, want)
+	}
+}
+
+func TestCodePageEncodeWithReplacementNegativeErrors(t *testing.T) {
+	if _, err := CP437.EncodeWithReplacement("ア", -1); err == nil {
+		t.Fatal("expected an error for an unmapped rune with a negative replacement")
+	}
+}
+
+func TestKatakanaCodePage(t *testing.T) {
+	got, err := Katakana.Encode("アイウ")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	want := []byte{0xB1, 0xB2, 0xB3}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Katakana.Encode = %v, want %v", got, want)
+	}
+}
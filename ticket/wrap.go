@@ -0,0 +1,104 @@
+package ticket
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Columns returns the printable column width for a font/character-size
+// combination on the MSD347's 58mm head: 42 columns for font A at
+// normal width, 33 at double width, 56/42 for font B, halving again
+// for each further doubling.
+func Columns(font Font, width int) int {
+	if width < 1 {
+		width = 1
+	}
+
+	cols := 42
+	if font == FontB {
+		cols = 56
+	}
+
+	switch width {
+	case 1:
+		return cols
+	case 2:
+		if font == FontB {
+			return 42
+		}
+		return 33
+	default:
+		cols = 33
+		if font == FontB {
+			cols = 42
+		}
+		for i := 2; i < width; i++ {
+			cols /= 2
+		}
+		return cols
+	}
+}
+
+// WrapText breaks s into lines no wider than cols columns, breaking on
+// spaces and preserving existing newlines as paragraph breaks. A word
+// wider than cols is hard-split across lines.
+func WrapText(s string, cols int) []string {
+	if cols < 1 {
+		cols = 1
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		lines = append(lines, wrapParagraph(paragraph, cols)...)
+	}
+
+	return lines
+}
+
+func wrapParagraph(s string, cols int) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := ""
+	lineLen := 0
+
+	for _, w := range words {
+		// Measure and cut by rune, not byte: code pages like Katakana
+		// map multi-byte UTF-8 runes to a single printed column, so
+		// len(w) would wrap far too early (or split a rune in half).
+		wr := []rune(w)
+		for len(wr) > cols {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+				lineLen = 0
+			}
+			lines = append(lines, string(wr[:cols]))
+			wr = wr[cols:]
+		}
+		w = string(wr)
+		wLen := utf8.RuneCountInString(w)
+
+		switch {
+		case line == "":
+			line = w
+			lineLen = wLen
+		case lineLen+1+wLen <= cols:
+			line += " " + w
+			lineLen += 1 + wLen
+		default:
+			lines = append(lines, line)
+			line = w
+			lineLen = wLen
+		}
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
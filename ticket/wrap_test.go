@@ -0,0 +1,71 @@
+package ticket
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumns(t *testing.T) {
+	cases := []struct {
+		font  Font
+		width int
+		want  int
+	}{
+		{FontA, 1, 42},
+		{FontA, 2, 33},
+		{FontA, 4, 8},
+		{FontB, 1, 56},
+		{FontB, 2, 42},
+		{FontA, 0, 42}, // below 1 clamps to 1
+	}
+
+	for _, c := range cases {
+		if got := Columns(c.font, c.width); got != c.want {
+			t.Errorf("Columns(%v, %d) = %d, want %d", c.font, c.width, got, c.want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	cases := []struct {
+		name string
+		s    string
+		cols int
+		want []string
+	}{
+		{"short line", "hello world", 20, []string{"hello world"}},
+		{
+			"wraps on spaces",
+			"the quick brown fox",
+			10,
+			[]string{"the quick", "brown fox"},
+		},
+		{
+			"hard-splits an overlong word",
+			"supercalifragilistic",
+			10,
+			[]string{"supercalif", "ragilistic"},
+		},
+		{
+			"preserves paragraph breaks",
+			"line one\nline two",
+			20,
+			[]string{"line one", "line two"},
+		},
+		{
+			"wraps by rune, not byte, for multi-byte code pages",
+			"アイウエオ カキクケコ",
+			5,
+			[]string{"アイウエオ", "カキクケコ"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := WrapText(c.s, c.cols)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("WrapText(%q, %d) = %q, want %q", c.s, c.cols, got, c.want)
+			}
+		})
+	}
+}
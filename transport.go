@@ -0,0 +1,50 @@
+package msd347
+
+import "io"
+
+// Transport is the minimal interface Printer needs to talk to a device:
+// write raw ESC/POS bytes out and read status replies back. The libusb
+// and usblp backends both implement it, and tests can supply their own
+// io.ReadWriteCloser in its place.
+type Transport = io.ReadWriteCloser
+
+// PacketSizer is implemented by transports that know a natural write
+// chunk size, such as a USB endpoint's wMaxPacketSize. PrintImage uses
+// it to size raster-data chunks instead of a hardcoded constant.
+type PacketSizer interface {
+	MaxPacketSize() int
+}
+
+// Option configures Connect.
+type Option func(*connectOptions)
+
+type connectOptions struct {
+	transport   Transport
+	caps        *PrinterCaps
+	maxDotWidth int
+}
+
+// WithTransport overrides device discovery with a caller-supplied
+// Transport. This is mainly useful in tests, where callers want to
+// exercise Printer without a real device attached.
+func WithTransport(t Transport) Option {
+	return func(o *connectOptions) {
+		o.transport = t
+	}
+}
+
+// WithPrinterCaps overrides the PrinterCaps Connect would otherwise
+// probe for, for callers who already know their unit's capabilities.
+func WithPrinterCaps(caps *PrinterCaps) Option {
+	return func(o *connectOptions) {
+		o.caps = caps
+	}
+}
+
+// WithMaxDotWidth overrides the printer's maximum raster dot width
+// (512, a 58mm head, by default). Use this for wider heads.
+func WithMaxDotWidth(dots int) Option {
+	return func(o *connectOptions) {
+		o.maxDotWidth = dots
+	}
+}
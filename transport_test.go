@@ -0,0 +1,42 @@
+package msd347
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// fakeTransport is a minimal in-memory Transport for tests: writes go to
+// Written, reads come from whatever's queued in To.
+type fakeTransport struct {
+	Written bytes.Buffer
+	To      bytes.Buffer
+}
+
+func (f *fakeTransport) Read(p []byte) (int, error)  { return f.To.Read(p) }
+func (f *fakeTransport) Write(p []byte) (int, error) { return f.Written.Write(p) }
+func (f *fakeTransport) Close() error                { return nil }
+
+func TestWithTransport(t *testing.T) {
+	ft := &fakeTransport{}
+
+	p, err := Connect(WithTransport(ft), WithPrinterCaps(defaultPrinterCaps()))
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	if p.conn != Transport(ft) {
+		t.Fatalf("Connect did not wire up the supplied transport")
+	}
+
+	if err := p.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	want := []byte{ESC, '@'}
+	if !bytes.Equal(ft.Written.Bytes(), want) {
+		t.Errorf("Initialize wrote %v, want %v", ft.Written.Bytes(), want)
+	}
+}
+
+var _ io.ReadWriteCloser = (*fakeTransport)(nil)